@@ -0,0 +1,21 @@
+// Package cni holds the root CNI network configuration document shared by
+// this plugin's Add/Delete calls.
+package cni
+
+import (
+	cniTypes "github.com/containernetworking/cni/pkg/types"
+)
+
+// NetworkConfig is the CNI network configuration document parsed from the
+// plugin's stdin. It embeds the standard CNI fields (cniVersion, name, type,
+// ipam, dns, ...) so the plugin can satisfy the CNI spec, plus this project's
+// own options.
+type NetworkConfig struct {
+	cniTypes.NetConf
+
+	// SwiftChainHookMode controls how the SWIFT jump rule is hooked into
+	// POSTROUTING: "insert" (the default when empty) places it ahead of
+	// rules appended later by kube-proxy, "append" restores the legacy
+	// ordering for operators who rely on it.
+	SwiftChainHookMode string `json:"swiftChainHookMode,omitempty"`
+}
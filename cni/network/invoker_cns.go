@@ -27,9 +27,12 @@ const (
 )
 
 type CNSIPAMInvoker struct {
-	podName      string
-	podNamespace string
-	cnsClient    cnsclient
+	podName         string
+	podNamespace    string
+	cnsClient       cnsclient
+	iptablesClient  iptables.Interface
+	ip6tablesClient iptables.Interface
+	routeManager    RouteManager
 }
 
 type IPv4ResultInfo struct {
@@ -42,17 +45,49 @@ type IPv4ResultInfo struct {
 	hostGateway        string
 }
 
+// IPv6ResultInfo mirrors IPv4ResultInfo for the IPv6 family. CNS may not
+// always return a v6 config for a given pod (e.g. single-stack clusters),
+// so callers should treat a nil *IPv6ResultInfo as "no IPv6 for this pod"
+// rather than an error.
+type IPv6ResultInfo struct {
+	podIPAddress       string
+	ncSubnetPrefix     uint8
+	ncPrimaryIP        string
+	ncGatewayIPAddress string
+	hostSubnet         string
+	hostPrimaryIP      string
+	hostGateway        string
+}
+
 func NewCNSInvoker(podName, namespace string, cnsClient cnsclient) *CNSIPAMInvoker {
 	return &CNSIPAMInvoker{
-		podName:      podName,
-		podNamespace: namespace,
-		cnsClient:    cnsClient,
+		podName:         podName,
+		podNamespace:    namespace,
+		cnsClient:       cnsClient,
+		iptablesClient:  iptables.NewClient(iptables.V4),
+		ip6tablesClient: iptables.NewClient(iptables.V6),
+		routeManager:    NewRouteManager(nil),
 	}
 }
 
-// Add uses the requestipconfig API in cns, and returns ipv4 and a nil ipv6 as CNS doesn't support IPv6 yet
+// NewCNSInvokerWithIptablesClients is identical to NewCNSInvoker but lets
+// callers (chiefly tests) inject the iptables.Interface implementations used
+// to program SNAT/chain-jump rules instead of shelling out for real.
+func NewCNSInvokerWithIptablesClients(podName, namespace string, cnsClient cnsclient, iptablesClient, ip6tablesClient iptables.Interface) *CNSIPAMInvoker {
+	invoker := NewCNSInvoker(podName, namespace, cnsClient)
+	invoker.iptablesClient = iptablesClient
+	invoker.ip6tablesClient = ip6tablesClient
+	return invoker
+}
+
+// Add uses the requestipconfig API in cns, and returns a dual-stack result
+// when CNS hands back both an IPv4 and an IPv6 config for the pod. If CNS
+// returns no IPv6 config, the second result is nil so callers degrade
+// gracefully to IPv4-only behavior. The IPv4 config is always required: a
+// response without a valid IPv4 config is an error, so IPv6-only pods are
+// not supported.
 func (invoker *CNSIPAMInvoker) Add( //nolint don't consider unnamedResult
-	_ *cni.NetworkConfig,
+	nwCfg *cni.NetworkConfig,
 	args *cniSkel.CmdArgs,
 	hostSubnetPrefix *net.IPNet,
 	options map[string]interface{}) (*cniTypesCurr.Result, *cniTypesCurr.Result, error) {
@@ -133,17 +168,64 @@ func (invoker *CNSIPAMInvoker) Add( //nolint don't consider unnamedResult
 		},
 	}
 
+	// CNS only populates the v6 network container config when the pod's
+	// network container is actually dual-stack; a nil response here means
+	// this cluster/pod is IPv4-only and we degrade gracefully.
+	var info6 *IPv6ResultInfo
+	var ncipnetV6 *net.IPNet
+	result6 := &cniTypesCurr.Result{}
+	if response.PodIpInfo.NetworkContainerPrimaryIPConfigV6 != nil && response.PodIpInfo.PodIPConfigV6 != nil {
+		info6 = &IPv6ResultInfo{
+			podIPAddress:       response.PodIpInfo.PodIPConfigV6.IPAddress,
+			ncSubnetPrefix:     response.PodIpInfo.NetworkContainerPrimaryIPConfigV6.IPSubnet.PrefixLength,
+			ncPrimaryIP:        response.PodIpInfo.NetworkContainerPrimaryIPConfigV6.IPSubnet.IPAddress,
+			ncGatewayIPAddress: response.PodIpInfo.NetworkContainerPrimaryIPConfigV6.GatewayIPAddress,
+			hostSubnet:         response.PodIpInfo.HostPrimaryIPInfo.Subnet,
+			hostPrimaryIP:      response.PodIpInfo.HostPrimaryIPInfo.PrimaryIP,
+			hostGateway:        response.PodIpInfo.HostPrimaryIPInfo.Gateway,
+		}
+
+		log.Printf("[cni-invoker-cns] Received ipv6 info %+v for pod %v", info6, podInfo)
+
+		ncgwV6 := net.ParseIP(info6.ncGatewayIPAddress)
+		if ncgwV6 == nil {
+			return nil, nil, fmt.Errorf("Gateway address %v from response is invalid", info6.ncGatewayIPAddress)
+		}
+
+		var ipV6 net.IP
+		ipV6, ncipnetV6, err = net.ParseCIDR(info6.podIPAddress + "/" + fmt.Sprint(info6.ncSubnetPrefix))
+		if ipV6 == nil {
+			return nil, nil, fmt.Errorf("Unable to parse IP from response: %v with err %v", info6.podIPAddress, err)
+		}
+
+		result6.IPs = []*cniTypesCurr.IPConfig{
+			{
+				Version: "6",
+				Address: net.IPNet{IP: ipV6, Mask: ncipnetV6.Mask},
+				Gateway: ncgwV6,
+			},
+		}
+		result6.Routes = []*cniTypes.Route{
+			{
+				Dst: network.Ipv6DefaultRouteDstPrefix,
+				GW:  ncgwV6,
+			},
+		}
+	} else {
+		result6 = nil
+	}
+
 	// set subnet prefix for host vm
-	err = setHostOptions(hostSubnetPrefix, ncipnet, options, &info)
+	err = invoker.setHostOptions(hostSubnetPrefix, ncipnet, ncipnetV6, ipconfig.PodInterfaceID, &info, info6, swiftChainHookMode(nwCfg))
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// first result is ipv4, second is ipv6, SWIFT doesn't currently support IPv6
-	return result, nil, nil
+	// first result is ipv4, second is ipv6 (nil when CNS returned no v6 config for this pod)
+	return result, result6, nil
 }
 
-func setHostOptions(hostSubnetPrefix, ncSubnetPrefix *net.IPNet, options map[string]interface{}, info *IPv4ResultInfo) error {
+func (invoker *CNSIPAMInvoker) setHostOptions(hostSubnetPrefix, ncSubnetPrefix, ncSubnetPrefixV6 *net.IPNet, podInterfaceID string, info *IPv4ResultInfo, info6 *IPv6ResultInfo, hookMode string) error {
 	// get the name of the primary IP address
 	_, hostIPNet, err := net.ParseCIDR(info.hostSubnet)
 	if err != nil {
@@ -168,34 +250,87 @@ func setHostOptions(hostSubnetPrefix, ncSubnetPrefix *net.IPNet, options map[str
 		return fmt.Errorf("Host Gateway %v from response is invalid", info.hostGateway)
 	}
 
-	// this route is needed when the vm on subnet A needs to send traffic to a pod in subnet B on a different vm
-	options[network.RoutesKey] = []network.RouteInfo{
-		{
-			Dst: *ncSubnetPrefix,
-			Gw:  hostGateway,
-		},
+	// this route is needed when the vm on subnet A needs to send traffic to a pod in subnet B on a different vm;
+	// the RouteManager programs it directly instead of handing it back through the CNI options map. Its
+	// reconcile loop runs only once Start is called, and NewCNSInvoker does not call Start
+	if err := invoker.routeManager.AddRoute(podInterfaceID, *ncSubnetPrefix, hostGateway); err != nil {
+		return fmt.Errorf("failed to add host route for pod interface %s: %w", podInterfaceID, err)
+	}
+
+	// by default the SWIFT jump rule is inserted at the top of POSTROUTING so
+	// it takes precedence over rules appended later by kube-proxy (e.g.
+	// KUBE-POSTROUTING); operators can opt back into the legacy append
+	// behavior via SwiftChainHookMode
+	position := iptables.Insert
+	if hookMode == SwiftChainHookModeAppend {
+		position = iptables.Append
 	}
 
 	azureDNSMatch := fmt.Sprintf(" -m addrtype ! --dst-type local -s %s -d %s -p %s --dport %d", ncSubnetPrefix.String(), iptables.AzureDNS, iptables.UDP, iptables.DNSPort)
 	azureIMDSMatch := fmt.Sprintf(" -m addrtype ! --dst-type local -s %s -d %s -p %s --dport %d", ncSubnetPrefix.String(), iptables.AzureIMDS, iptables.TCP, iptables.HTTPPort)
-
 	snatPrimaryIPJump := fmt.Sprintf("%s --to %s", iptables.Snat, info.ncPrimaryIP)
 	// we need to snat IMDS traffic to node IP, this sets up snat '--to'
 	snatHostIPJump := fmt.Sprintf("%s --to %s", iptables.Snat, info.hostPrimaryIP)
-	options[network.IPTablesKey] = []iptables.IPTableEntry{
-		iptables.GetCreateChainCmd(iptables.V4, iptables.Nat, iptables.Swift),
-		iptables.GetAppendIptableRuleCmd(iptables.V4, iptables.Nat, iptables.Postrouting, "", iptables.Swift),
-		// add a snat rule to primary NC IP for DNS
-		iptables.GetInsertIptableRuleCmd(iptables.V4, iptables.Nat, iptables.Swift, azureDNSMatch, snatPrimaryIPJump),
-		// add a snat rule to node IP for IMDS http traffic
-		iptables.GetInsertIptableRuleCmd(iptables.V4, iptables.Nat, iptables.Swift, azureIMDSMatch, snatHostIPJump),
+
+	if err := invoker.iptablesClient.EnsureChain(iptables.Nat, iptables.Swift); err != nil {
+		return err
+	}
+
+	if err := invoker.iptablesClient.EnsureRule(position, iptables.Nat, iptables.Postrouting, "", iptables.Swift); err != nil {
+		return err
+	}
+
+	// add a snat rule to primary NC IP for DNS
+	if err := invoker.iptablesClient.EnsureRule(iptables.Insert, iptables.Nat, iptables.Swift, azureDNSMatch, snatPrimaryIPJump); err != nil {
+		return err
+	}
+
+	// add a snat rule to node IP for IMDS http traffic
+	if err := invoker.iptablesClient.EnsureRule(iptables.Insert, iptables.Nat, iptables.Swift, azureIMDSMatch, snatHostIPJump); err != nil {
+		return err
+	}
+
+	// mirror the v4 chain hook and DNS SNAT rule for v6 when CNS handed back a dual-stack config
+	if info6 != nil && ncSubnetPrefixV6 != nil {
+		if err := invoker.setHostOptionsV6(ncSubnetPrefixV6, info, info6, position); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// Delete calls into the releaseipconfiguration API in CNS
-func (invoker *CNSIPAMInvoker) Delete(address *net.IPNet, _ *cni.NetworkConfig, args *cniSkel.CmdArgs, _ map[string]interface{}) error {
+func (invoker *CNSIPAMInvoker) setHostOptionsV6(ncSubnetPrefixV6 *net.IPNet, _ *IPv4ResultInfo, info6 *IPv6ResultInfo, position string) error {
+	azureDNSMatchV6 := fmt.Sprintf(" -m addrtype ! --dst-type local -s %s -d %s -p %s --dport %d", ncSubnetPrefixV6.String(), iptables.AzureDNSv6, iptables.UDP, iptables.DNSPort)
+	snatPrimaryIPJumpV6 := fmt.Sprintf("%s --to %s", iptables.Snat, info6.ncPrimaryIP)
+
+	if err := invoker.ip6tablesClient.EnsureChain(iptables.Nat, iptables.Swift); err != nil {
+		return err
+	}
+
+	if err := invoker.ip6tablesClient.EnsureRule(position, iptables.Nat, iptables.Postrouting, "", iptables.Swift); err != nil {
+		return err
+	}
+
+	// add a snat rule to primary NC IP for DNS; Azure IMDS has no IPv6
+	// endpoint, so unlike the v4 path there is no IMDS rule to mirror here
+	return invoker.ip6tablesClient.EnsureRule(iptables.Insert, iptables.Nat, iptables.Swift, azureDNSMatchV6, snatPrimaryIPJumpV6)
+}
+
+// swiftChainHookMode reads the operator-configured hook mode off the CNI
+// network config, defaulting to insert (the safer choice when kube-proxy
+// shares the node) when unset.
+func swiftChainHookMode(nwCfg *cni.NetworkConfig) string {
+	if nwCfg == nil || nwCfg.SwiftChainHookMode == "" {
+		return SwiftChainHookModeInsert
+	}
+
+	return nwCfg.SwiftChainHookMode
+}
+
+// Delete calls into the releaseipconfiguration API in CNS for each address
+// passed in. addressV6 is nil for single-stack pods.
+func (invoker *CNSIPAMInvoker) Delete(address, addressV6 *net.IPNet, _ *cni.NetworkConfig, args *cniSkel.CmdArgs, _ map[string]interface{}) error {
 	// Parse Pod arguments.
 	podInfo := cns.KubernetesPodInfo{
 		PodName:      invoker.podName,
@@ -227,5 +362,24 @@ func (invoker *CNSIPAMInvoker) Delete(address *net.IPNet, _ *cni.NetworkConfig,
 		return fmt.Errorf("failed to release IP %v with err %w", address, err)
 	}
 
+	// release the v6 address too when this pod was dual-stack; single-stack
+	// pods never populate addressV6 so there's nothing more to release
+	if addressV6 != nil {
+		reqV6 := cns.IPConfigRequest{
+			OrchestratorContext: orchestratorContext,
+			PodInterfaceID:      GetEndpointID(args),
+			InfraContainerID:    args.ContainerID,
+			DesiredIPAddress:    addressV6.IP.String(),
+		}
+
+		if err := invoker.cnsClient.ReleaseIPAddress(context.TODO(), reqV6); err != nil {
+			return fmt.Errorf("failed to release IP %v with err %w", addressV6, err)
+		}
+	}
+
+	if err := invoker.routeManager.RemoveRoutes(GetEndpointID(args)); err != nil {
+		return fmt.Errorf("failed to remove host routes for pod interface %s: %w", GetEndpointID(args), err)
+	}
+
 	return nil
 }
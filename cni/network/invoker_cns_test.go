@@ -0,0 +1,140 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/cni/network/mocks"
+	"github.com/Azure/azure-container-networking/iptables"
+	iptablesmocks "github.com/Azure/azure-container-networking/iptables/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+func mustParseIPNet(t *testing.T, cidr string) *net.IPNet {
+	t.Helper()
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatalf("failed to parse CIDR %s: %s", cidr, err.Error())
+	}
+	return ipnet
+}
+
+func TestSetHostOptionsProgramsExactRuleSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		hookMode string
+		info6    *IPv6ResultInfo
+		wantV4   string
+	}{
+		{
+			name:     "v4 only, default insert hook",
+			hookMode: "",
+			wantV4:   iptables.Insert,
+		},
+		{
+			name:     "v4 only, append hook",
+			hookMode: SwiftChainHookModeAppend,
+			wantV4:   iptables.Append,
+		},
+	}
+
+	info := &IPv4ResultInfo{
+		ncPrimaryIP:   "10.0.0.4",
+		hostPrimaryIP: "10.0.0.1",
+		hostGateway:   "10.0.0.1",
+		hostSubnet:    "10.0.0.0/24",
+	}
+	ncSubnetPrefix := mustParseIPNet(t, "10.240.0.0/24")
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			v4 := iptablesmocks.NewMockInterface(ctrl)
+			v4.EXPECT().EnsureChain(iptables.Nat, iptables.Swift).Return(nil)
+			v4.EXPECT().EnsureRule(tt.wantV4, iptables.Nat, iptables.Postrouting, "", iptables.Swift).Return(nil)
+			v4.EXPECT().EnsureRule(iptables.Insert, iptables.Nat, iptables.Swift,
+				" -m addrtype ! --dst-type local -s 10.240.0.0/24 -d 168.63.129.16 -p udp --dport 53", "SNAT --to 10.0.0.4").Return(nil)
+			v4.EXPECT().EnsureRule(iptables.Insert, iptables.Nat, iptables.Swift,
+				" -m addrtype ! --dst-type local -s 10.240.0.0/24 -d 169.254.169.254 -p tcp --dport 80", "SNAT --to 10.0.0.1").Return(nil)
+
+			v6 := iptablesmocks.NewMockInterface(ctrl)
+
+			routeManager := mocks.NewMockRouteManager(ctrl)
+			routeManager.EXPECT().AddRoute("pod1", *ncSubnetPrefix, net.ParseIP(info.hostGateway)).Return(nil)
+
+			invoker := &CNSIPAMInvoker{iptablesClient: v4, ip6tablesClient: v6, routeManager: routeManager}
+
+			var hostSubnetPrefix net.IPNet
+			if err := invoker.setHostOptions(&hostSubnetPrefix, ncSubnetPrefix, nil, "pod1", info, nil, tt.hookMode); err != nil {
+				t.Fatalf("setHostOptions() returned error %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestSetHostOptionsDualStackAlsoProgramsV6(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	info := &IPv4ResultInfo{
+		ncPrimaryIP:   "10.0.0.4",
+		hostPrimaryIP: "10.0.0.1",
+		hostGateway:   "10.0.0.1",
+		hostSubnet:    "10.0.0.0/24",
+	}
+	info6 := &IPv6ResultInfo{
+		ncPrimaryIP: "fd00::4",
+	}
+	ncSubnetPrefix := mustParseIPNet(t, "10.240.0.0/24")
+	ncSubnetPrefixV6 := mustParseIPNet(t, "fd00:240::/64")
+
+	v4 := iptablesmocks.NewMockInterface(ctrl)
+	v4.EXPECT().EnsureChain(iptables.Nat, iptables.Swift).Return(nil)
+	v4.EXPECT().EnsureRule(iptables.Insert, iptables.Nat, iptables.Postrouting, "", iptables.Swift).Return(nil)
+	v4.EXPECT().EnsureRule(iptables.Insert, iptables.Nat, iptables.Swift, gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	v6 := iptablesmocks.NewMockInterface(ctrl)
+	v6.EXPECT().EnsureChain(iptables.Nat, iptables.Swift).Return(nil)
+	v6.EXPECT().EnsureRule(iptables.Insert, iptables.Nat, iptables.Postrouting, "", iptables.Swift).Return(nil)
+	v6.EXPECT().EnsureRule(iptables.Insert, iptables.Nat, iptables.Swift, gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	routeManager := mocks.NewMockRouteManager(ctrl)
+	routeManager.EXPECT().AddRoute("pod1", *ncSubnetPrefix, net.ParseIP(info.hostGateway)).Return(nil)
+
+	invoker := &CNSIPAMInvoker{iptablesClient: v4, ip6tablesClient: v6, routeManager: routeManager}
+
+	var hostSubnetPrefix net.IPNet
+	if err := invoker.setHostOptions(&hostSubnetPrefix, ncSubnetPrefix, ncSubnetPrefixV6, "pod1", info, info6, ""); err != nil {
+		t.Fatalf("setHostOptions() returned error %s", err.Error())
+	}
+}
+
+func TestSetHostOptionsAddsHostRouteViaRouteManager(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	info := &IPv4ResultInfo{
+		ncPrimaryIP:   "10.0.0.4",
+		hostPrimaryIP: "10.0.0.1",
+		hostGateway:   "10.0.0.1",
+		hostSubnet:    "10.0.0.0/24",
+	}
+	ncSubnetPrefix := mustParseIPNet(t, "10.240.0.0/24")
+
+	v4 := iptablesmocks.NewMockInterface(ctrl)
+	v4.EXPECT().EnsureChain(iptables.Nat, iptables.Swift).Return(nil)
+	v4.EXPECT().EnsureRule(gomock.Any(), iptables.Nat, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	routeManager := mocks.NewMockRouteManager(ctrl)
+	routeManager.EXPECT().AddRoute("pod1", *ncSubnetPrefix, net.ParseIP(info.hostGateway)).Return(nil)
+
+	invoker := &CNSIPAMInvoker{iptablesClient: v4, ip6tablesClient: iptablesmocks.NewMockInterface(ctrl), routeManager: routeManager}
+
+	var hostSubnetPrefix net.IPNet
+	if err := invoker.setHostOptions(&hostSubnetPrefix, ncSubnetPrefix, nil, "pod1", info, nil, ""); err != nil {
+		t.Fatalf("setHostOptions() returned error %s", err.Error())
+	}
+}
@@ -0,0 +1,169 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-container-networking/log"
+)
+
+const routeReconcileInterval = 30 * time.Second
+
+// RouteManager takes ownership of the host routes installed for SWIFT pods.
+// It programs routes directly instead of stuffing a RouteInfo slice into
+// the CNI options map for a downstream consumer to apply, tracks them keyed
+// by (podInterfaceID, dst), and periodically reconciles the host's routing
+// table against that tracked state, reinstalling routes that went missing.
+// Explicit cleanup when a pod goes away is RemoveRoutes' job, not
+// reconcile's: podInterfaceID is never a link that exists on the host, so
+// reconcile cannot use it to detect a pod's removal.
+type RouteManager interface {
+	// AddRoute resolves gw to the host's real uplink interface, programs a
+	// route to dst via gw on it, and starts tracking it under
+	// (podInterfaceID, dst).
+	AddRoute(podInterfaceID string, dst net.IPNet, gw net.IP) error
+	// RemoveRoutes deletes and stops tracking every route staged for
+	// podInterfaceID.
+	RemoveRoutes(podInterfaceID string) error
+	// Start runs the periodic reconcile loop until ctx is canceled.
+	Start(ctx context.Context)
+}
+
+// routeProgrammer is the OS-specific half of RouteManager: the actual
+// syscall/CLI invocation used to add, remove, or check for a single route.
+// Linux programs routes via the repo's netlink.Interface abstraction;
+// Windows shells out to the route.exe CLI.
+type routeProgrammer interface {
+	addRoute(linkName string, dst net.IPNet, gw net.IP) error
+	delRoute(linkName string, dst net.IPNet, gw net.IP) error
+	routeExists(linkName string, dst net.IPNet) (bool, error)
+	// hostLinkName resolves gw to the name of the host's real uplink
+	// interface, i.e. the interface these host-level routes must actually
+	// be programmed against. podInterfaceID is a CNS/CNI pod-interface
+	// identifier, not a link that exists on the host, so it can never be
+	// used here.
+	hostLinkName(gw net.IP) (string, error)
+}
+
+// routeKey identifies a single tracked route by the pod interface it was
+// programmed for and the destination it routes.
+type routeKey struct {
+	podInterfaceID string
+	dst            string
+}
+
+type trackedRoute struct {
+	linkName string
+	dst      net.IPNet
+	gw       net.IP
+}
+
+// routeManager is the concrete RouteManager shared by the Linux and Windows
+// builds; only the routeProgrammer differs between them.
+type routeManager struct {
+	programmer routeProgrammer
+	routes     sync.Map // routeKey -> trackedRoute
+}
+
+func newRouteManager(programmer routeProgrammer) *routeManager {
+	return &routeManager{programmer: programmer}
+}
+
+func (r *routeManager) AddRoute(podInterfaceID string, dst net.IPNet, gw net.IP) error {
+	linkName, err := r.programmer.hostLinkName(gw)
+	if err != nil {
+		return fmt.Errorf("failed to resolve host interface for route to %s via %s: %w", dst.String(), gw.String(), err)
+	}
+
+	if err := r.programmer.addRoute(linkName, dst, gw); err != nil {
+		return fmt.Errorf("failed to add route to %s via %s on %s: %w", dst.String(), gw.String(), linkName, err)
+	}
+
+	key := routeKey{podInterfaceID: podInterfaceID, dst: dst.String()}
+	r.routes.Store(key, trackedRoute{linkName: linkName, dst: dst, gw: gw})
+
+	return nil
+}
+
+// RemoveRoutes deletes every route tracked for podInterfaceID, continuing
+// past individual failures so one stuck route doesn't block the rest. It
+// returns the first error encountered, if any.
+func (r *routeManager) RemoveRoutes(podInterfaceID string) error {
+	var firstErr error
+
+	r.routes.Range(func(key, value interface{}) bool {
+		k, _ := key.(routeKey)
+		if k.podInterfaceID != podInterfaceID {
+			return true
+		}
+
+		tr, _ := value.(trackedRoute)
+		if err := r.programmer.delRoute(tr.linkName, tr.dst, tr.gw); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to remove route to %s on %s: %w", tr.dst.String(), podInterfaceID, err)
+		}
+
+		r.routes.Delete(key)
+
+		return true
+	})
+
+	return firstErr
+}
+
+// Start runs the reconcile loop until ctx is canceled.
+func (r *routeManager) Start(ctx context.Context) {
+	ticker := time.NewTicker(routeReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reconcile()
+		}
+	}
+}
+
+// reconcile reinstalls tracked routes missing from the host, and drops
+// tracking for any route whose host uplink interface has itself gone away
+// (e.g. the NIC was hot-unplugged). tr.linkName is empty when the
+// routeProgrammer's hostLinkName is a no-op (e.g. Windows, where route.exe
+// resolves the outgoing interface itself), so there is no link name to check
+// for existence and this branch is skipped.
+func (r *routeManager) reconcile() {
+	r.routes.Range(func(key, value interface{}) bool {
+		k, _ := key.(routeKey)
+		tr, _ := value.(trackedRoute)
+
+		if tr.linkName != "" {
+			if _, err := net.InterfaceByName(tr.linkName); err != nil {
+				log.Printf("[cni-route-manager] %s no longer exists, dropping tracked route to %s", tr.linkName, tr.dst.String())
+				r.routes.Delete(key)
+
+				return true
+			}
+		}
+
+		exists, err := r.programmer.routeExists(tr.linkName, tr.dst)
+		if err != nil {
+			log.Printf("[cni-route-manager] failed to check route to %s on %s: %v", tr.dst.String(), tr.linkName, err)
+			return true
+		}
+
+		if exists {
+			return true
+		}
+
+		log.Printf("[cni-route-manager] route to %s missing from %s, reinstalling", tr.dst.String(), tr.linkName)
+
+		if err := r.programmer.addRoute(tr.linkName, tr.dst, tr.gw); err != nil {
+			log.Printf("[cni-route-manager] failed to reinstall route to %s on %s: %v", tr.dst.String(), k.podInterfaceID, err)
+		}
+
+		return true
+	})
+}
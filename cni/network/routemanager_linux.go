@@ -0,0 +1,54 @@
+//go:build linux
+
+package network
+
+import (
+	"net"
+
+	"github.com/Azure/azure-container-networking/netlink"
+)
+
+// linuxRouteProgrammer programs routes via the repo's netlink.Interface
+// abstraction.
+type linuxRouteProgrammer struct {
+	client netlink.Interface
+}
+
+func (p *linuxRouteProgrammer) addRoute(linkName string, dst net.IPNet, gw net.IP) error {
+	return p.client.RouteAdd(&netlink.Route{LinkName: linkName, Dst: dst, Gw: gw})
+}
+
+func (p *linuxRouteProgrammer) delRoute(linkName string, dst net.IPNet, gw net.IP) error {
+	return p.client.RouteDel(&netlink.Route{LinkName: linkName, Dst: dst, Gw: gw})
+}
+
+func (p *linuxRouteProgrammer) hostLinkName(gw net.IP) (string, error) {
+	return p.client.RouteGetLink(gw)
+}
+
+func (p *linuxRouteProgrammer) routeExists(linkName string, dst net.IPNet) (bool, error) {
+	routes, err := p.client.RouteList(linkName)
+	if err != nil {
+		return false, err
+	}
+
+	for _, route := range routes {
+		if route.Dst.String() == dst.String() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// NewRouteManager creates a RouteManager that programs routes via
+// netlinkClient. Passing nil falls back to the real `ip route` binary,
+// which production callers should avoid in favor of an explicit
+// netlink.NewClient().
+func NewRouteManager(netlinkClient netlink.Interface) RouteManager {
+	if netlinkClient == nil {
+		netlinkClient = netlink.NewClient()
+	}
+
+	return newRouteManager(&linuxRouteProgrammer{client: netlinkClient})
+}
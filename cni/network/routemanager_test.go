@@ -0,0 +1,218 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+var errProgram = errors.New("route: exit status 1")
+
+type fakeRouteProgrammer struct {
+	addCalls       []trackedRoute
+	delCalls       []trackedRoute
+	exists         map[string]bool
+	addErr         error
+	delErr         error
+	hostLinkErr    error
+	hostLinkName   string
+	hostLinkIsNoop bool
+}
+
+func (f *fakeRouteProgrammer) hostLinkName(net.IP) (string, error) {
+	if f.hostLinkErr != nil {
+		return "", f.hostLinkErr
+	}
+
+	if f.hostLinkIsNoop {
+		return "", nil
+	}
+
+	if f.hostLinkName != "" {
+		return f.hostLinkName, nil
+	}
+
+	return "eth0", nil
+}
+
+func (f *fakeRouteProgrammer) addRoute(linkName string, dst net.IPNet, gw net.IP) error {
+	f.addCalls = append(f.addCalls, trackedRoute{linkName: linkName, dst: dst, gw: gw})
+	return f.addErr
+}
+
+func (f *fakeRouteProgrammer) delRoute(linkName string, dst net.IPNet, gw net.IP) error {
+	f.delCalls = append(f.delCalls, trackedRoute{linkName: linkName, dst: dst, gw: gw})
+	return f.delErr
+}
+
+func (f *fakeRouteProgrammer) routeExists(linkName string, dst net.IPNet) (bool, error) {
+	return f.exists[linkName+dst.String()], nil
+}
+
+func mustParseRouteCIDR(t *testing.T, cidr string) net.IPNet {
+	t.Helper()
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatalf("failed to parse CIDR %s: %s", cidr, err.Error())
+	}
+	return *ipnet
+}
+
+func TestAddRouteProgramsAndTracksRoute(t *testing.T) {
+	programmer := &fakeRouteProgrammer{}
+	r := newRouteManager(programmer)
+
+	dst := mustParseRouteCIDR(t, "10.240.0.0/24")
+	gw := net.ParseIP("10.0.0.1")
+
+	if err := r.AddRoute("pod1", dst, gw); err != nil {
+		t.Fatalf("AddRoute() returned error %s", err.Error())
+	}
+
+	if len(programmer.addCalls) != 1 || programmer.addCalls[0].linkName != "eth0" {
+		t.Fatalf("expected 1 addRoute call against the resolved host link, got %+v", programmer.addCalls)
+	}
+
+	key := routeKey{podInterfaceID: "pod1", dst: dst.String()}
+	if _, ok := r.routes.Load(key); !ok {
+		t.Error("expected route to be tracked under its pod interface ID after AddRoute()")
+	}
+}
+
+func TestAddRouteReturnsErrorWithoutTracking(t *testing.T) {
+	programmer := &fakeRouteProgrammer{addErr: errProgram}
+	r := newRouteManager(programmer)
+
+	dst := mustParseRouteCIDR(t, "10.240.0.0/24")
+
+	if err := r.AddRoute("pod1", dst, net.ParseIP("10.0.0.1")); err == nil {
+		t.Fatal("AddRoute() returned nil error, want non-nil")
+	}
+
+	key := routeKey{podInterfaceID: "pod1", dst: dst.String()}
+	if _, ok := r.routes.Load(key); ok {
+		t.Error("expected route not to be tracked when addRoute fails")
+	}
+}
+
+func TestRemoveRoutesDeletesOnlyMatchingPodInterface(t *testing.T) {
+	programmer := &fakeRouteProgrammer{}
+	r := newRouteManager(programmer)
+
+	dstA := mustParseRouteCIDR(t, "10.240.0.0/24")
+	dstB := mustParseRouteCIDR(t, "10.241.0.0/24")
+	gw := net.ParseIP("10.0.0.1")
+
+	if err := r.AddRoute("pod1", dstA, gw); err != nil {
+		t.Fatalf("AddRoute() returned error %s", err.Error())
+	}
+	if err := r.AddRoute("pod2", dstB, gw); err != nil {
+		t.Fatalf("AddRoute() returned error %s", err.Error())
+	}
+
+	if err := r.RemoveRoutes("pod1"); err != nil {
+		t.Fatalf("RemoveRoutes() returned error %s", err.Error())
+	}
+
+	if len(programmer.delCalls) != 1 || programmer.delCalls[0].linkName != "eth0" {
+		t.Fatalf("expected exactly one delRoute call against the resolved host link, got %+v", programmer.delCalls)
+	}
+	if _, ok := r.routes.Load(routeKey{podInterfaceID: "pod1", dst: dstA.String()}); ok {
+		t.Error("expected pod1's route to stop being tracked after RemoveRoutes()")
+	}
+	if _, ok := r.routes.Load(routeKey{podInterfaceID: "pod2", dst: dstB.String()}); !ok {
+		t.Error("expected pod2's route to still be tracked")
+	}
+}
+
+func TestReconcileReinstallsMissingRoute(t *testing.T) {
+	programmer := &fakeRouteProgrammer{exists: map[string]bool{}, hostLinkName: "lo"}
+	r := newRouteManager(programmer)
+
+	dst := mustParseRouteCIDR(t, "10.240.0.0/24")
+	gw := net.ParseIP("10.0.0.1")
+
+	// "lo" is present on every host this test runs on, so the interface
+	// existence check passes and only the missing-route check is exercised.
+	if err := r.AddRoute("pod1", dst, gw); err != nil {
+		t.Fatalf("AddRoute() returned error %s", err.Error())
+	}
+
+	r.reconcile()
+
+	if len(programmer.addCalls) != 2 {
+		t.Fatalf("expected the initial add plus one reinstall, got %d calls", len(programmer.addCalls))
+	}
+}
+
+func TestReconcileSkipsRouteAlreadyPresent(t *testing.T) {
+	dst := mustParseRouteCIDR(t, "10.240.0.0/24")
+	programmer := &fakeRouteProgrammer{exists: map[string]bool{"lo" + dst.String(): true}, hostLinkName: "lo"}
+	r := newRouteManager(programmer)
+
+	if err := r.AddRoute("pod1", dst, net.ParseIP("10.0.0.1")); err != nil {
+		t.Fatalf("AddRoute() returned error %s", err.Error())
+	}
+
+	r.reconcile()
+
+	if len(programmer.addCalls) != 1 {
+		t.Errorf("expected no reinstall for an already-present route, got %d add calls", len(programmer.addCalls))
+	}
+}
+
+func TestReconcileDropsRouteWhenHostLinkGone(t *testing.T) {
+	programmer := &fakeRouteProgrammer{exists: map[string]bool{}, hostLinkName: "nonexistent-test-iface"}
+	r := newRouteManager(programmer)
+
+	dst := mustParseRouteCIDR(t, "10.240.0.0/24")
+	podInterfaceID := "pod1"
+
+	if err := r.AddRoute(podInterfaceID, dst, net.ParseIP("10.0.0.1")); err != nil {
+		t.Fatalf("AddRoute() returned error %s", err.Error())
+	}
+
+	r.reconcile()
+
+	if _, ok := r.routes.Load(routeKey{podInterfaceID: podInterfaceID, dst: dst.String()}); ok {
+		t.Error("expected route to be dropped once its host link no longer exists")
+	}
+}
+
+func TestReconcileReinstallsMissingRouteWhenHostLinkNameIsNoop(t *testing.T) {
+	// hostLinkName returning "" mirrors the Windows routeProgrammer, where
+	// route.exe resolves the outgoing interface itself. reconcile must not
+	// treat that empty linkName as a vanished interface and drop tracking.
+	dst := mustParseRouteCIDR(t, "10.240.0.0/24")
+	programmer := &fakeRouteProgrammer{exists: map[string]bool{}, hostLinkIsNoop: true}
+	r := newRouteManager(programmer)
+
+	podInterfaceID := "pod1"
+	if err := r.AddRoute(podInterfaceID, dst, net.ParseIP("10.0.0.1")); err != nil {
+		t.Fatalf("AddRoute() returned error %s", err.Error())
+	}
+
+	r.reconcile()
+
+	if _, ok := r.routes.Load(routeKey{podInterfaceID: podInterfaceID, dst: dst.String()}); !ok {
+		t.Error("expected route to stay tracked when hostLinkName is a no-op")
+	}
+	if len(programmer.addCalls) != 2 {
+		t.Fatalf("expected the initial add plus one reinstall, got %d calls", len(programmer.addCalls))
+	}
+}
+
+func TestAddRouteReturnsErrorWhenHostLinkResolutionFails(t *testing.T) {
+	programmer := &fakeRouteProgrammer{hostLinkErr: errProgram}
+	r := newRouteManager(programmer)
+
+	dst := mustParseRouteCIDR(t, "10.240.0.0/24")
+
+	if err := r.AddRoute("pod1", dst, net.ParseIP("10.0.0.1")); err == nil {
+		t.Fatal("AddRoute() returned nil error, want non-nil")
+	}
+
+	if len(programmer.addCalls) != 0 {
+		t.Errorf("expected addRoute not to be called when host link resolution fails, got %d calls", len(programmer.addCalls))
+	}
+}
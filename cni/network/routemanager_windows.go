@@ -0,0 +1,58 @@
+//go:build windows
+
+package network
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/netlink"
+)
+
+// windowsRouteProgrammer programs routes via the route.exe CLI, mirroring
+// how the Linux build shells out to ip route via netlink.Interface.
+// linkName is accepted for interface symmetry with the Linux programmer but
+// unused: route.exe resolves the outgoing interface from the gateway.
+type windowsRouteProgrammer struct{}
+
+// hostLinkName is a no-op on Windows: route.exe resolves the outgoing
+// interface from the gateway itself, so there is no link name to resolve
+// up front.
+func (p *windowsRouteProgrammer) hostLinkName(_ net.IP) (string, error) {
+	return "", nil
+}
+
+func (p *windowsRouteProgrammer) addRoute(_ string, dst net.IPNet, gw net.IP) error {
+	return runRoute("add", "-p", dst.IP.String(), "mask", net.IP(dst.Mask).String(), gw.String())
+}
+
+func (p *windowsRouteProgrammer) delRoute(_ string, dst net.IPNet, _ net.IP) error {
+	return runRoute("delete", dst.IP.String())
+}
+
+func (p *windowsRouteProgrammer) routeExists(_ string, dst net.IPNet) (bool, error) {
+	out, err := exec.Command("route", "print", dst.IP.String()).CombinedOutput() //nolint:gosec // fixed binary, args built from a parsed IP
+	if err != nil {
+		return false, fmt.Errorf("route print %s failed with err %w: %s", dst.IP.String(), err, out)
+	}
+
+	return strings.Contains(string(out), dst.IP.String()), nil
+}
+
+func runRoute(args ...string) error {
+	out, err := exec.Command("route", args...).CombinedOutput() //nolint:gosec // fixed binary, args built from parsed IPs
+	if err != nil {
+		return fmt.Errorf("route %s failed with err %w: %s", strings.Join(args, " "), err, out)
+	}
+
+	return nil
+}
+
+// NewRouteManager creates a RouteManager that programs routes via the
+// route.exe CLI. netlinkClient is accepted for call-site symmetry with the
+// Linux build but unused on Windows.
+func NewRouteManager(_ netlink.Interface) RouteManager {
+	return newRouteManager(&windowsRouteProgrammer{})
+}
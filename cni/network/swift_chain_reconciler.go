@@ -0,0 +1,105 @@
+package network
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-container-networking/iptables"
+	"github.com/Azure/azure-container-networking/log"
+)
+
+const (
+	// SwiftChainHookModeInsert installs the SWIFT jump rule at the top of
+	// POSTROUTING (position 1) so it always takes precedence over rules
+	// appended later by kube-proxy or other components.
+	SwiftChainHookModeInsert = "insert"
+	// SwiftChainHookModeAppend preserves the legacy behavior of appending
+	// the SWIFT jump rule to the end of POSTROUTING.
+	SwiftChainHookModeAppend = "append"
+
+	swiftChainReconcileInterval = 30 * time.Second
+)
+
+// SwiftChainReconciler periodically verifies that the jump rule from
+// POSTROUTING into the SWIFT chain is still in the expected position and
+// reinstalls it if some other component (most commonly kube-proxy rewriting
+// KUBE-POSTROUTING) has pushed it down the chain. Operators who opt into
+// SwiftChainHookModeAppend are assumed to manage ordering themselves, so the
+// reconciler is a no-op for them.
+type SwiftChainReconciler struct {
+	client   iptables.Interface
+	hookMode string
+}
+
+// NewSwiftChainReconciler creates a reconciler for the given hook mode. An
+// empty hookMode defaults to insert, which is the safer default for SWIFT
+// pods sharing a node with kube-proxy.
+func NewSwiftChainReconciler(client iptables.Interface, hookMode string) *SwiftChainReconciler {
+	if hookMode == "" {
+		hookMode = SwiftChainHookModeInsert
+	}
+
+	return &SwiftChainReconciler{client: client, hookMode: hookMode}
+}
+
+// Start runs the reconcile loop until ctx is canceled.
+func (r *SwiftChainReconciler) Start(ctx context.Context) {
+	ticker := time.NewTicker(swiftChainReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.reconcile(); err != nil {
+				log.Printf("[cni-swift-chain-reconciler] failed to reconcile SWIFT jump rule: %v", err)
+			}
+		}
+	}
+}
+
+func (r *SwiftChainReconciler) reconcile() error {
+	if r.hookMode == SwiftChainHookModeAppend {
+		return nil
+	}
+
+	atTop, err := r.jumpRuleAtTop()
+	if err != nil {
+		return err
+	}
+
+	if atTop {
+		return nil
+	}
+
+	log.Printf("[cni-swift-chain-reconciler] SWIFT jump rule has drifted from POSTROUTING position 1, reinstalling")
+
+	if err := r.client.DeleteRule(iptables.Nat, iptables.Postrouting, "", iptables.Swift); err != nil {
+		return err
+	}
+
+	return r.client.EnsureRule(iptables.Insert, iptables.Nat, iptables.Postrouting, "", iptables.Swift)
+}
+
+// jumpRuleAtTop reports whether the first rule programmed in POSTROUTING is
+// the jump into the SWIFT chain.
+func (r *SwiftChainReconciler) jumpRuleAtTop() (bool, error) {
+	rules, err := r.client.ListRules(iptables.Nat, iptables.Postrouting)
+	if err != nil {
+		return false, err
+	}
+
+	for _, rule := range rules {
+		if !strings.HasPrefix(rule, "-A ") {
+			// -P (policy) and other non-append entries don't count as
+			// programmed rule positions
+			continue
+		}
+
+		return strings.Contains(rule, "-j "+iptables.Swift), nil
+	}
+
+	return false, nil
+}
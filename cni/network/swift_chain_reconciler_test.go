@@ -0,0 +1,96 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-container-networking/iptables"
+)
+
+// fakeIptablesClient is a hand-rolled iptables.Interface test double that
+// tracks the POSTROUTING rule order well enough to exercise the reconciler's
+// drift-detection logic without shelling out.
+type fakeIptablesClient struct {
+	iptables.Interface
+	postroutingRules []string
+	deleted          int
+	inserted         int
+}
+
+func (f *fakeIptablesClient) ListRules(_, _ string) ([]string, error) {
+	return f.postroutingRules, nil
+}
+
+func (f *fakeIptablesClient) DeleteRule(_, _, _, target string) error {
+	f.deleted++
+	filtered := f.postroutingRules[:0]
+	for _, rule := range f.postroutingRules {
+		if rule != "-A POSTROUTING -j "+target {
+			filtered = append(filtered, rule)
+		}
+	}
+	f.postroutingRules = filtered
+	return nil
+}
+
+func (f *fakeIptablesClient) EnsureRule(position, _, _, _, target string) error {
+	f.inserted++
+	rule := "-A POSTROUTING -j " + target
+	if position == iptables.Insert {
+		f.postroutingRules = append([]string{rule}, f.postroutingRules...)
+	} else {
+		f.postroutingRules = append(f.postroutingRules, rule)
+	}
+	return nil
+}
+
+func TestSwiftChainReconcileInsertModeReinstallsDriftedRule(t *testing.T) {
+	client := &fakeIptablesClient{postroutingRules: []string{
+		"-A POSTROUTING -j KUBE-POSTROUTING",
+		"-A POSTROUTING -j SWIFT",
+	}}
+	reconciler := NewSwiftChainReconciler(client, SwiftChainHookModeInsert)
+
+	if err := reconciler.reconcile(); err != nil {
+		t.Fatalf("reconcile() returned error %s", err.Error())
+	}
+
+	if client.deleted != 1 || client.inserted != 1 {
+		t.Fatalf("expected reconcile to delete and reinsert the drifted rule once, got deleted=%d inserted=%d", client.deleted, client.inserted)
+	}
+
+	if client.postroutingRules[0] != "-A POSTROUTING -j SWIFT" {
+		t.Fatalf("expected SWIFT jump rule at top of POSTROUTING after reconcile, got %v", client.postroutingRules)
+	}
+}
+
+func TestSwiftChainReconcileInsertModeNoopWhenAlreadyAtTop(t *testing.T) {
+	client := &fakeIptablesClient{postroutingRules: []string{
+		"-A POSTROUTING -j SWIFT",
+		"-A POSTROUTING -j KUBE-POSTROUTING",
+	}}
+	reconciler := NewSwiftChainReconciler(client, SwiftChainHookModeInsert)
+
+	if err := reconciler.reconcile(); err != nil {
+		t.Fatalf("reconcile() returned error %s", err.Error())
+	}
+
+	if client.deleted != 0 || client.inserted != 0 {
+		t.Fatalf("expected no reinstall when jump rule is already at position 1, got deleted=%d inserted=%d", client.deleted, client.inserted)
+	}
+}
+
+func TestSwiftChainReconcileAppendModeNoop(t *testing.T) {
+	client := &fakeIptablesClient{postroutingRules: []string{
+		"-A POSTROUTING -j KUBE-POSTROUTING",
+		"-A POSTROUTING -j SWIFT",
+	}}
+	reconciler := NewSwiftChainReconciler(client, SwiftChainHookModeAppend)
+
+	if err := reconciler.reconcile(); err != nil {
+		t.Fatalf("reconcile() returned error %s", err.Error())
+	}
+
+	if client.deleted != 0 || client.inserted != 0 {
+		t.Fatalf("expected append mode to leave rule ordering alone, got deleted=%d inserted=%d", client.deleted, client.inserted)
+	}
+}
@@ -0,0 +1,104 @@
+package iptables
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// run executes version (iptables or ip6tables) with args and returns its
+// combined output.
+func run(version string, args ...string) (string, error) {
+	out, err := exec.Command(version, args...).CombinedOutput() //nolint:gosec // fixed, non-user-controlled arguments
+	if err != nil {
+		return "", fmt.Errorf("%s %s failed with err %w: %s", version, strings.Join(args, " "), err, out)
+	}
+
+	return string(out), nil
+}
+
+// restoreBinary returns the iptables-restore/ip6tables-restore binary name
+// for version ("iptables" or "ip6tables").
+func restoreBinary(version string) string {
+	return version + "-restore"
+}
+
+// client is the production Interface implementation, backed by the real
+// iptables/ip6tables binaries for the given family (V4 or V6).
+type client struct {
+	version string
+}
+
+// NewClient returns an Interface that shells out to version ("iptables" or
+// "ip6tables") for every operation.
+func NewClient(version string) Interface {
+	return &client{version: version}
+}
+
+func (c *client) EnsureChain(table, chain string) error {
+	rules, err := c.ListRules(table, chain)
+	if err == nil && rules != nil {
+		// creating an already-existing chain would fail, but a readable
+		// ListRules result means the chain is already there
+		return nil
+	}
+
+	return Run(GetCreateChainCmd(c.version, table, chain))
+}
+
+func (c *client) EnsureRule(position, table, chain, match, target string) error {
+	rules, err := c.ListRules(table, chain)
+	if err != nil {
+		return err
+	}
+
+	rule := strings.TrimSpace(fmt.Sprintf("%s -j %s", match, target))
+	for _, existing := range rules {
+		if strings.Contains(existing, rule) {
+			// idempotent: the rule is already programmed
+			return nil
+		}
+	}
+
+	if position == Insert {
+		return Run(GetInsertIptableRuleCmd(c.version, table, chain, match, target))
+	}
+
+	return Run(GetAppendIptableRuleCmd(c.version, table, chain, match, target))
+}
+
+func (c *client) DeleteRule(table, chain, match, target string) error {
+	return Run(GetDeleteIptableRuleCmd(c.version, table, chain, match, target))
+}
+
+func (c *client) ListRules(table, chain string) ([]string, error) {
+	out, err := run(c.version, "-t", table, "-S", chain)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	rules := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line != "" {
+			rules = append(rules, line)
+		}
+	}
+
+	return rules, nil
+}
+
+// Restore feeds document into iptables-restore/ip6tables-restore with
+// --noflush, so the existing ruleset is amended rather than replaced.
+func (c *client) Restore(document string) error {
+	cmd := exec.Command(restoreBinary(c.version), "--noflush") //nolint:gosec // fixed binary name, document is generated internally
+	cmd.Stdin = bytes.NewBufferString(document)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s --noflush failed with err %w: %s", restoreBinary(c.version), err, out)
+	}
+
+	return nil
+}
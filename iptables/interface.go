@@ -0,0 +1,33 @@
+package iptables
+
+// Interface abstracts the iptables/ip6tables operations this repo needs so
+// that callers like CNSIPAMInvoker can be unit-tested without shelling out
+// to the real binaries. It is intentionally narrow - just the verbs the CNI
+// and NPM dataplanes actually use - rather than a full iptables client.
+type Interface interface {
+	// EnsureChain creates chain in table if it does not already exist. It is
+	// a no-op if the chain is already present.
+	EnsureChain(table, chain string) error
+	// EnsureRule inserts or appends a rule matching match and acting via
+	// target into chain, unless an identical rule is already programmed.
+	// position controls where a newly-inserted rule lands ("insert" puts it
+	// at the top of chain, "append" puts it at the bottom).
+	EnsureRule(position, table, chain, match, target string) error
+	// DeleteRule removes a rule matching match/target from chain, if present.
+	DeleteRule(table, chain, match, target string) error
+	// ListRules returns the rules currently programmed in chain, in the
+	// order iptables would apply them.
+	ListRules(table, chain string) ([]string, error)
+	// Restore applies document - a full iptables-save/iptables-restore
+	// formatted payload covering one or more tables - in a single
+	// iptables-restore invocation. It is used by callers that batch many
+	// rule changes together instead of issuing one EnsureRule/DeleteRule
+	// per change.
+	Restore(document string) error
+}
+
+// Rule insert/append positions accepted by EnsureRule.
+const (
+	Insert = "insert"
+	Append = "append"
+)
@@ -0,0 +1,90 @@
+// Package iptables builds and runs the iptables/ip6tables commands used to
+// program SNAT and chain-jump rules for SWIFT pods.
+package iptables
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// iptables/ip6tables binary selector, passed as the first argument to the
+// Get*Cmd helpers below so v4 and v6 rules can share the same builders.
+const (
+	V4 = "iptables"
+	V6 = "ip6tables"
+)
+
+// Well-known table and chain names.
+const (
+	Nat         = "nat"
+	Postrouting = "POSTROUTING"
+	Swift       = "SWIFT"
+)
+
+// Protocols and ports matched when building SNAT rules for Azure DNS/IMDS.
+const (
+	UDP = "udp"
+	TCP = "tcp"
+
+	DNSPort  = 53
+	HTTPPort = 80
+
+	Snat = "SNAT"
+
+	AzureDNS   = "168.63.129.16"
+	AzureIMDS  = "169.254.169.254"
+	AzureDNSv6 = "fd3e:4f5a:5b6c::1"
+)
+
+// IPTableEntry is a single iptables/ip6tables invocation, ready to be
+// executed by Run.
+type IPTableEntry struct {
+	Version string
+	Params  string
+}
+
+// GetCreateChainCmd builds a command that creates a new chain in table.
+func GetCreateChainCmd(version, table, chain string) IPTableEntry {
+	return IPTableEntry{
+		Version: version,
+		Params:  fmt.Sprintf("-t %s -N %s", table, chain),
+	}
+}
+
+// GetAppendIptableRuleCmd builds a command that appends a rule jumping from
+// chain to target, to the end of chain.
+func GetAppendIptableRuleCmd(version, table, chain, match, target string) IPTableEntry {
+	return IPTableEntry{
+		Version: version,
+		Params:  fmt.Sprintf("-t %s -A %s %s -j %s", table, chain, match, target),
+	}
+}
+
+// GetInsertIptableRuleCmd builds a command that inserts a rule matching
+// match and jumping/acting via target at the top (position 1) of chain.
+func GetInsertIptableRuleCmd(version, table, chain, match, target string) IPTableEntry {
+	return IPTableEntry{
+		Version: version,
+		Params:  fmt.Sprintf("-t %s -I %s 1 %s -j %s", table, chain, match, target),
+	}
+}
+
+// GetDeleteIptableRuleCmd builds a command that deletes a rule matching the
+// same match/target pair used to insert or append it.
+func GetDeleteIptableRuleCmd(version, table, chain, match, target string) IPTableEntry {
+	return IPTableEntry{
+		Version: version,
+		Params:  fmt.Sprintf("-t %s -D %s %s -j %s", table, chain, match, target),
+	}
+}
+
+// Run executes a single iptables/ip6tables command against the host.
+func Run(entry IPTableEntry) error {
+	out, err := exec.Command(entry.Version, strings.Fields(entry.Params)...).CombinedOutput() //nolint:gosec // entries are built from fixed, non-user-controlled arguments
+	if err != nil {
+		return fmt.Errorf("%s %s failed with err %w: %s", entry.Version, entry.Params, err, out)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package netlink
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+)
+
+// client is the production Interface implementation, backed by the `ip`
+// binary.
+type client struct{}
+
+// NewClient returns an Interface that shells out to `ip route` for every
+// operation.
+func NewClient() Interface {
+	return &client{}
+}
+
+func (c *client) RouteAdd(route *Route) error {
+	return run(routeArgs("add", route))
+}
+
+func (c *client) RouteDel(route *Route) error {
+	return run(routeArgs("del", route))
+}
+
+func (c *client) RouteList(linkName string) ([]*Route, error) {
+	args := []string{"route", "list", "dev", linkName}
+
+	out, err := exec.Command("ip", args...).CombinedOutput() //nolint:gosec // fixed binary, args built from fixed flags and a caller-supplied link name
+	if err != nil {
+		return nil, fmt.Errorf("ip %s failed with err %w: %s", strings.Join(args, " "), err, out)
+	}
+
+	var routes []*Route
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+
+		route, err := parseRouteLine(linkName, line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse route %q for %s: %w", line, linkName, err)
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+func (c *client) RouteGetLink(gw net.IP) (string, error) {
+	args := []string{"route", "get", gw.String()}
+
+	out, err := exec.Command("ip", args...).CombinedOutput() //nolint:gosec // fixed binary, args built from a parsed IP
+	if err != nil {
+		return "", fmt.Errorf("ip %s failed with err %w: %s", strings.Join(args, " "), err, out)
+	}
+
+	fields := strings.Fields(string(out))
+	for i, field := range fields {
+		if field == "dev" && i+1 < len(fields) {
+			return fields[i+1], nil
+		}
+	}
+
+	return "", fmt.Errorf("no dev found in ip %s output: %s", strings.Join(args, " "), out)
+}
+
+// routeArgs builds the `ip route <verb> ...` argument list for route.
+func routeArgs(verb string, route *Route) []string {
+	args := []string{"route", verb, route.Dst.String()}
+	if route.Gw != nil {
+		args = append(args, "via", route.Gw.String())
+	}
+
+	return append(args, "dev", route.LinkName)
+}
+
+// parseRouteLine parses a single line of `ip route list dev linkName`
+// output, e.g. "10.240.0.0/24 via 10.0.0.1 dev eth0", "10.240.0.0/24 dev eth0 scope link",
+// "default via 10.0.0.1 dev eth0" or "10.0.0.1 dev eth0 scope link".
+func parseRouteLine(linkName, line string) (*Route, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty route line")
+	}
+
+	dstField := fields[0]
+	switch {
+	case dstField == "default":
+		dstField = "0.0.0.0/0"
+	case !strings.Contains(dstField, "/"):
+		// host routes are printed without a prefix length
+		if strings.Contains(dstField, ":") {
+			dstField += "/128"
+		} else {
+			dstField += "/32"
+		}
+	}
+
+	_, dst, err := net.ParseCIDR(dstField)
+	if err != nil {
+		return nil, err
+	}
+
+	route := &Route{LinkName: linkName, Dst: *dst}
+	for i := 1; i < len(fields)-1; i++ {
+		if fields[i] == "via" {
+			route.Gw = net.ParseIP(fields[i+1])
+		}
+	}
+
+	return route, nil
+}
+
+// run executes an `ip` command with args and returns its combined output.
+func run(args []string) error {
+	out, err := exec.Command("ip", args...).CombinedOutput() //nolint:gosec // fixed binary, args built from fixed flags and caller-supplied route fields
+	if err != nil {
+		return fmt.Errorf("ip %s failed with err %w: %s", strings.Join(args, " "), err, out)
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+// Package netlink abstracts the route/link programming this repo needs on
+// Linux so callers can be unit-tested without touching the host's routing
+// table.
+package netlink
+
+import "net"
+
+// Route describes a single route to be programmed via RouteAdd/RouteDel.
+type Route struct {
+	LinkName string
+	Dst      net.IPNet
+	Gw       net.IP
+}
+
+// Interface abstracts the netlink operations this repo needs.
+type Interface interface {
+	RouteAdd(route *Route) error
+	RouteDel(route *Route) error
+	RouteList(linkName string) ([]*Route, error)
+	// RouteGetLink resolves gw to the name of the interface the host would
+	// actually route traffic to it through.
+	RouteGetLink(gw net.IP) (string, error)
+}
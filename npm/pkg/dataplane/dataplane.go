@@ -0,0 +1,131 @@
+package dataplane
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-container-networking/iptables"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+)
+
+// DataPlane is the Linux GenericDataplane implementation: it stages ipset
+// and iptables mutations in the ipsets.Manager/policies.PolicyManager dirty
+// caches and flushes both in one batch per ApplyDataPlane call.
+type DataPlane struct {
+	iptablesClient iptables.Interface
+	ipsetClient    ipsets.Interface
+	policyMgr      *policies.PolicyManager
+	ipsetMgr       *ipsets.Manager
+}
+
+// NewDataPlane creates a DataPlane backed by iptablesClient and ipsetClient.
+// Passing nil for either falls back to the real binary, which production
+// callers should avoid in favor of explicit clients.
+func NewDataPlane(iptablesClient iptables.Interface, ipsetClient ipsets.Interface) *DataPlane {
+	if iptablesClient == nil {
+		iptablesClient = iptables.NewClient(iptables.V4)
+	}
+	if ipsetClient == nil {
+		ipsetClient = ipsets.NewClient()
+	}
+
+	return &DataPlane{
+		iptablesClient: iptablesClient,
+		ipsetClient:    ipsetClient,
+		policyMgr:      policies.NewPolicyManager(iptablesClient),
+		ipsetMgr:       ipsets.NewManager(ipsetClient),
+	}
+}
+
+// InitializeDataPlane ensures the base chain every policy chain hooks into
+// is present before any policy is programmed.
+func (dp *DataPlane) InitializeDataPlane() error {
+	return dp.iptablesClient.EnsureChain(iptables.Nat, policies.BaseChain)
+}
+
+// ResetDataPlane discards any pending, unapplied changes staged on this
+// DataPlane. It does not touch the dataplane already programmed on the
+// host; callers that need a clean host state should remove policies/ipsets
+// individually via RemovePolicy/DeleteIPSet before the next ApplyDataPlane.
+func (dp *DataPlane) ResetDataPlane() error {
+	dp.policyMgr = policies.NewPolicyManager(dp.iptablesClient)
+	dp.ipsetMgr = ipsets.NewManager(dp.ipsetClient)
+	return nil
+}
+
+func (dp *DataPlane) CreateIPSet(setMetadata *ipsets.IPSetMetadata) {
+	dp.ipsetMgr.CreateIPSet(setMetadata)
+}
+
+func (dp *DataPlane) DeleteIPSet(setMetadata *ipsets.IPSetMetadata) {
+	dp.ipsetMgr.DeleteIPSet(setMetadata)
+}
+
+func (dp *DataPlane) AddToSet(setNames []*ipsets.IPSetMetadata, ip, podKey string) error {
+	return dp.ipsetMgr.AddMember(setNames, ip, podKey)
+}
+
+func (dp *DataPlane) RemoveFromSet(setNames []*ipsets.IPSetMetadata, ip, podKey string) error {
+	return dp.ipsetMgr.RemoveMember(setNames, ip, podKey)
+}
+
+// AddToList stages every set in setNames as a member of listName.
+func (dp *DataPlane) AddToList(listName *ipsets.IPSetMetadata, setNames []*ipsets.IPSetMetadata) error {
+	for _, setMetadata := range setNames {
+		if err := dp.ipsetMgr.AddMember([]*ipsets.IPSetMetadata{listName}, setMetadata.GetPrefixName(), ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveFromList stages every set in setNames for removal from listName.
+func (dp *DataPlane) RemoveFromList(listName *ipsets.IPSetMetadata, setNames []*ipsets.IPSetMetadata) error {
+	for _, setMetadata := range setNames {
+		if err := dp.ipsetMgr.RemoveMember([]*ipsets.IPSetMetadata{listName}, setMetadata.GetPrefixName(), ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (dp *DataPlane) AddPolicy(policy *policies.NPMNetworkPolicy) error {
+	return dp.policyMgr.AddPolicy(policy, nil)
+}
+
+func (dp *DataPlane) RemovePolicy(policyName string) error {
+	return dp.policyMgr.RemovePolicy(policyName, nil)
+}
+
+func (dp *DataPlane) UpdatePolicy(policy *policies.NPMNetworkPolicy) error {
+	return dp.policyMgr.UpdatePolicy(policy, nil)
+}
+
+// UpdatePod is a no-op until pod-to-ipset membership tracking (named ports,
+// pod selectors) is wired up on top of ipsets.Manager.
+func (dp *DataPlane) UpdatePod(_ *UpdateNPMPod) error {
+	return nil
+}
+
+// ApplyDataPlane flushes the ipset batch before the iptables batch, since a
+// policy's rules may reference sets staged in the same sync.
+func (dp *DataPlane) ApplyDataPlane() error {
+	if err := dp.ipsetMgr.ApplyIPSets(); err != nil {
+		return fmt.Errorf("failed to apply ipsets: %w", err)
+	}
+
+	if err := dp.policyMgr.ApplyPolicies(); err != nil {
+		return fmt.Errorf("failed to apply policies: %w", err)
+	}
+
+	return nil
+}
+
+// PendingDataPlaneApply renders the iptables-restore and ipset restore
+// documents that ApplyDataPlane would flush right now, without flushing
+// them.
+func (dp *DataPlane) PendingDataPlaneApply() (iptablesRestore, ipsetRestore string) {
+	return dp.policyMgr.PendingRestore(), dp.ipsetMgr.PendingRestore()
+}
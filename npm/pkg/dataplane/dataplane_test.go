@@ -0,0 +1,88 @@
+package dataplane
+
+import (
+	"errors"
+	"testing"
+
+	iptablesmocks "github.com/Azure/azure-container-networking/iptables/mocks"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	ipsetsmocks "github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets/mocks"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+	"github.com/golang/mock/gomock"
+)
+
+var errRestore = errors.New("restore: exit status 1")
+
+func TestPendingDataPlaneApplyRendersExactBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dp := NewDataPlane(iptablesmocks.NewMockInterface(ctrl), ipsetsmocks.NewMockInterface(ctrl))
+
+	dp.CreateIPSet(&ipsets.IPSetMetadata{Name: "frontend", Type: "hash:ip"})
+	if err := dp.AddPolicy(&policies.NPMNetworkPolicy{PolicyKey: "allow-frontend"}); err != nil {
+		t.Fatalf("AddPolicy() returned error %s", err.Error())
+	}
+
+	wantIPTables := "*nat\n" +
+		"-N AZURE-NPM-allow-frontend\n" +
+		"-A AZURE-NPM  -j AZURE-NPM-allow-frontend\n" +
+		"COMMIT\n"
+	wantIPSet := "create hash:ip-frontend hash:ip\n"
+
+	gotIPTables, gotIPSet := dp.PendingDataPlaneApply()
+	if gotIPTables != wantIPTables {
+		t.Errorf("iptables restore = %q, want %q", gotIPTables, wantIPTables)
+	}
+	if gotIPSet != wantIPSet {
+		t.Errorf("ipset restore = %q, want %q", gotIPSet, wantIPSet)
+	}
+}
+
+func TestApplyDataPlaneAppliesIPSetsBeforePolicies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	iptablesClient := iptablesmocks.NewMockInterface(ctrl)
+	ipsetClient := ipsetsmocks.NewMockInterface(ctrl)
+
+	gomock.InOrder(
+		ipsetClient.EXPECT().Restore(gomock.Any()).Return(nil),
+		iptablesClient.EXPECT().Restore(gomock.Any()).Return(nil),
+	)
+
+	dp := NewDataPlane(iptablesClient, ipsetClient)
+	dp.CreateIPSet(&ipsets.IPSetMetadata{Name: "frontend", Type: "hash:ip"})
+	if err := dp.AddPolicy(&policies.NPMNetworkPolicy{PolicyKey: "allow-frontend"}); err != nil {
+		t.Fatalf("AddPolicy() returned error %s", err.Error())
+	}
+
+	if err := dp.ApplyDataPlane(); err != nil {
+		t.Fatalf("ApplyDataPlane() returned error %s", err.Error())
+	}
+
+	gotIPTables, gotIPSet := dp.PendingDataPlaneApply()
+	if gotIPTables != "" || gotIPSet != "" {
+		t.Errorf("pending batch after apply = (%q, %q), want empty", gotIPTables, gotIPSet)
+	}
+}
+
+func TestApplyDataPlaneStopsAtFirstFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	iptablesClient := iptablesmocks.NewMockInterface(ctrl)
+	ipsetClient := ipsetsmocks.NewMockInterface(ctrl)
+	// iptablesClient.Restore must not be called: the ipset batch fails first.
+	ipsetClient.EXPECT().Restore(gomock.Any()).Return(errRestore)
+
+	dp := NewDataPlane(iptablesClient, ipsetClient)
+	dp.CreateIPSet(&ipsets.IPSetMetadata{Name: "frontend", Type: "hash:ip"})
+	if err := dp.AddPolicy(&policies.NPMNetworkPolicy{PolicyKey: "allow-frontend"}); err != nil {
+		t.Fatalf("AddPolicy() returned error %s", err.Error())
+	}
+
+	if err := dp.ApplyDataPlane(); err == nil {
+		t.Error("ApplyDataPlane() returned nil error, want non-nil")
+	}
+}
@@ -0,0 +1,29 @@
+package ipsets
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+)
+
+// client is the production Interface implementation, backed by the real
+// ipset binary.
+type client struct{}
+
+// NewClient returns an Interface that shells out to ipset restore for every
+// batch.
+func NewClient() Interface {
+	return &client{}
+}
+
+func (c *client) Restore(document string) error {
+	cmd := exec.Command("ipset", "restore") //nolint:gosec // fixed binary name, document is generated internally
+	cmd.Stdin = bytes.NewBufferString(document)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ipset restore failed with err %w: %s", err, out)
+	}
+
+	return nil
+}
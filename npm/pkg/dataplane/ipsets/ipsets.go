@@ -0,0 +1,27 @@
+// Package ipsets models the ipset metadata NPM programs into the dataplane
+// (pod/namespace selectors, named ports, etc.) and the sets/lists built from
+// them.
+package ipsets
+
+// IPSetMetadata identifies a single ipset or ipset list by name and type.
+type IPSetMetadata struct {
+	Name string
+	Type string
+}
+
+// GetPrefixName returns the name ipset itself is programmed under for this
+// metadata: its type and name joined by a dash. It does not hash or bound
+// the result to IPSET_MAXNAMELEN (31 chars) - callers are responsible for
+// keeping Name short enough that the real ipset binary accepts it.
+func (i *IPSetMetadata) GetPrefixName() string {
+	return i.Type + "-" + i.Name
+}
+
+// Interface abstracts the ipset operations the NPM dataplane needs, mirroring
+// iptables.Interface: a narrow surface that can be backed by the real ipset
+// binary or a gomock test double.
+type Interface interface {
+	// Restore applies document - an ipset-save/ipset-restore formatted
+	// payload - in a single ipset restore invocation.
+	Restore(document string) error
+}
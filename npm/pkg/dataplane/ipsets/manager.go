@@ -0,0 +1,179 @@
+package ipsets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// memberOp is the pending mutation recorded for a single ipset member.
+type memberOp int
+
+const (
+	opAdd memberOp = iota
+	opRemove
+)
+
+// setState accumulates the pending create/destroy and membership changes
+// staged for one ipset since the last successful ApplyIPSets, keyed by the
+// set's prefix name.
+type setState struct {
+	metadata IPSetMetadata
+	create   bool
+	destroy  bool
+	members  map[string]memberOp
+}
+
+// Manager stages ipset mutations in a dirty-set cache keyed by ipset name
+// and flushes them as a single `ipset restore` stream per ApplyIPSets call,
+// mirroring policies.PolicyManager.
+type Manager struct {
+	ipsetClient     Interface
+	dirtyCache      map[string]*setState
+	lastGoodRestore string
+}
+
+// NewManager creates a Manager backed by ipsetClient. Passing nil falls back
+// to the real ipset binary, which production callers should avoid in favor
+// of an explicit NewClient().
+func NewManager(ipsetClient Interface) *Manager {
+	if ipsetClient == nil {
+		ipsetClient = NewClient()
+	}
+
+	return &Manager{ipsetClient: ipsetClient, dirtyCache: make(map[string]*setState)}
+}
+
+func (m *Manager) entry(setMetadata *IPSetMetadata) *setState {
+	name := setMetadata.GetPrefixName()
+
+	s, ok := m.dirtyCache[name]
+	if !ok {
+		s = &setState{metadata: *setMetadata, members: make(map[string]memberOp)}
+		m.dirtyCache[name] = s
+	}
+
+	return s
+}
+
+// CreateIPSet stages the creation of setMetadata. It is a no-op until the
+// next ApplyIPSets.
+func (m *Manager) CreateIPSet(setMetadata *IPSetMetadata) {
+	s := m.entry(setMetadata)
+	s.create = true
+	s.destroy = false
+}
+
+// DeleteIPSet stages the destruction of setMetadata, discarding any
+// membership changes staged for it earlier in this batch.
+func (m *Manager) DeleteIPSet(setMetadata *IPSetMetadata) {
+	s := m.entry(setMetadata)
+	s.create = false
+	s.destroy = true
+	s.members = make(map[string]memberOp)
+}
+
+// AddMember stages member's addition to every set in setNames, collapsing
+// any remove staged for the same member earlier in this batch. podKey is
+// reserved for reference-counted membership and is currently unused.
+func (m *Manager) AddMember(setNames []*IPSetMetadata, member, _ string) error {
+	for _, setMetadata := range setNames {
+		m.entry(setMetadata).members[member] = opAdd
+	}
+
+	return nil
+}
+
+// RemoveMember stages member's removal from every set in setNames,
+// collapsing any add staged for the same member earlier in this batch.
+// podKey is reserved for reference-counted membership and is currently
+// unused.
+func (m *Manager) RemoveMember(setNames []*IPSetMetadata, member, _ string) error {
+	for _, setMetadata := range setNames {
+		m.entry(setMetadata).members[member] = opRemove
+	}
+
+	return nil
+}
+
+// PendingRestore renders the ipset-restore document for every set staged
+// since the last successful ApplyIPSets. Sets and members are rendered in
+// sorted order so the same batch always produces the same document. It does
+// not clear the dirty cache.
+func (m *Manager) PendingRestore() string {
+	if len(m.dirtyCache) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, name := range m.sortedNames() {
+		s := m.dirtyCache[name]
+
+		if s.destroy {
+			fmt.Fprintf(&b, "destroy %s\n", name)
+			continue
+		}
+
+		if s.create {
+			fmt.Fprintf(&b, "create %s %s\n", name, s.metadata.Type)
+		}
+
+		for _, member := range s.sortedMembers() {
+			switch s.members[member] {
+			case opAdd:
+				fmt.Fprintf(&b, "add %s %s\n", name, member)
+			case opRemove:
+				fmt.Fprintf(&b, "del %s %s\n", name, member)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// ApplyIPSets flushes every set staged since the last successful call as a
+// single `ipset restore` invocation and clears the dirty cache on success.
+// On failure the previous good document is replayed, mirroring
+// policies.PolicyManager.ApplyPolicies. ipset restore stops at the first
+// failing line without undoing the lines before it, and the replay does not
+// remove them either, so entries of the failed batch applied before that
+// line stay on the host.
+func (m *Manager) ApplyIPSets() error {
+	document := m.PendingRestore()
+	if document == "" {
+		return nil
+	}
+
+	if err := m.ipsetClient.Restore(document); err != nil {
+		if m.lastGoodRestore != "" {
+			_ = m.ipsetClient.Restore(m.lastGoodRestore)
+		}
+
+		return fmt.Errorf("ipset restore failed: %w", err)
+	}
+
+	m.lastGoodRestore = document
+	m.dirtyCache = make(map[string]*setState)
+
+	return nil
+}
+
+func (m *Manager) sortedNames() []string {
+	names := make([]string, 0, len(m.dirtyCache))
+	for name := range m.dirtyCache {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (s *setState) sortedMembers() []string {
+	members := make([]string, 0, len(s.members))
+	for member := range s.members {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	return members
+}
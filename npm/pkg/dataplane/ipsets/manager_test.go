@@ -0,0 +1,125 @@
+package ipsets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+var errRestore = errors.New("ipset restore: exit status 1")
+
+func TestCreateIPSetStagesRestoreDocument(t *testing.T) {
+	mgr := NewManager(nil)
+
+	mgr.CreateIPSet(&IPSetMetadata{Name: "frontend", Type: "hash:ip"})
+
+	want := "create hash:ip-frontend hash:ip\n"
+	if got := mgr.PendingRestore(); got != want {
+		t.Errorf("PendingRestore() = %q, want %q", got, want)
+	}
+}
+
+func TestAddMemberCollapsesWithPriorRemoveInBatch(t *testing.T) {
+	mgr := NewManager(nil)
+	set := &IPSetMetadata{Name: "frontend", Type: "hash:ip"}
+
+	if err := mgr.RemoveMember([]*IPSetMetadata{set}, "10.0.0.1", "pod-a"); err != nil {
+		t.Fatalf("RemoveMember() returned error %s", err.Error())
+	}
+	if err := mgr.AddMember([]*IPSetMetadata{set}, "10.0.0.1", "pod-a"); err != nil {
+		t.Fatalf("AddMember() returned error %s", err.Error())
+	}
+
+	want := "add hash:ip-frontend 10.0.0.1\n"
+	if got := mgr.PendingRestore(); got != want {
+		t.Errorf("PendingRestore() = %q, want %q", got, want)
+	}
+}
+
+func TestDeleteIPSetDiscardsStagedMembers(t *testing.T) {
+	mgr := NewManager(nil)
+	set := &IPSetMetadata{Name: "frontend", Type: "hash:ip"}
+
+	if err := mgr.AddMember([]*IPSetMetadata{set}, "10.0.0.1", "pod-a"); err != nil {
+		t.Fatalf("AddMember() returned error %s", err.Error())
+	}
+	mgr.DeleteIPSet(set)
+
+	want := "destroy hash:ip-frontend\n"
+	if got := mgr.PendingRestore(); got != want {
+		t.Errorf("PendingRestore() = %q, want %q", got, want)
+	}
+}
+
+func TestApplyIPSetsCallsRestoreAndClearsCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ipsetClient := mocks.NewMockInterface(ctrl)
+	ipsetClient.EXPECT().Restore("create hash:ip-frontend hash:ip\n").Return(nil)
+
+	mgr := NewManager(ipsetClient)
+	mgr.CreateIPSet(&IPSetMetadata{Name: "frontend", Type: "hash:ip"})
+
+	if err := mgr.ApplyIPSets(); err != nil {
+		t.Fatalf("ApplyIPSets() returned error %s", err.Error())
+	}
+	if got := mgr.PendingRestore(); got != "" {
+		t.Errorf("PendingRestore() after apply = %q, want empty", got)
+	}
+}
+
+func TestApplyIPSetsWithNoChangesIsNoop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ipsetClient := mocks.NewMockInterface(ctrl)
+	// no EXPECT() calls: Restore must not be invoked for an empty batch.
+
+	mgr := NewManager(ipsetClient)
+
+	if err := mgr.ApplyIPSets(); err != nil {
+		t.Fatalf("ApplyIPSets() returned error %s", err.Error())
+	}
+}
+
+func TestApplyIPSetsReturnsErrorOnRestoreFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ipsetClient := mocks.NewMockInterface(ctrl)
+	ipsetClient.EXPECT().Restore(gomock.Any()).Return(errRestore)
+
+	mgr := NewManager(ipsetClient)
+	mgr.CreateIPSet(&IPSetMetadata{Name: "frontend", Type: "hash:ip"})
+
+	if err := mgr.ApplyIPSets(); err == nil {
+		t.Error("ApplyIPSets() returned nil error, want non-nil")
+	}
+}
+
+func TestApplyIPSetsRollsBackOnFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ipsetClient := mocks.NewMockInterface(ctrl)
+	gomock.InOrder(
+		ipsetClient.EXPECT().Restore(gomock.Any()).Return(nil),        // the good batch
+		ipsetClient.EXPECT().Restore(gomock.Any()).Return(errRestore), // the bad batch
+		ipsetClient.EXPECT().Restore(gomock.Any()).Return(nil),        // rollback to the good document
+	)
+
+	mgr := NewManager(ipsetClient)
+
+	mgr.CreateIPSet(&IPSetMetadata{Name: "frontend", Type: "hash:ip"})
+	if err := mgr.ApplyIPSets(); err != nil {
+		t.Fatalf("ApplyIPSets() returned error %s", err.Error())
+	}
+
+	mgr.CreateIPSet(&IPSetMetadata{Name: "backend", Type: "hash:ip"})
+	if err := mgr.ApplyIPSets(); err == nil {
+		t.Error("ApplyIPSets() returned nil error, want non-nil")
+	}
+}
@@ -133,6 +133,21 @@ func (mr *MockGenericDataplaneMockRecorder) InitializeDataPlane() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitializeDataPlane", reflect.TypeOf((*MockGenericDataplane)(nil).InitializeDataPlane))
 }
 
+// PendingDataPlaneApply mocks base method.
+func (m *MockGenericDataplane) PendingDataPlaneApply() (string, string) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingDataPlaneApply")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	return ret0, ret1
+}
+
+// PendingDataPlaneApply indicates an expected call of PendingDataPlaneApply.
+func (mr *MockGenericDataplaneMockRecorder) PendingDataPlaneApply() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingDataPlaneApply", reflect.TypeOf((*MockGenericDataplane)(nil).PendingDataPlaneApply))
+}
+
 // RemoveFromList mocks base method.
 func (m *MockGenericDataplane) RemoveFromList(listName *ipsets.IPSetMetadata, setNames []*ipsets.IPSetMetadata) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,173 @@
+// Package policies translates Kubernetes NetworkPolicy objects into
+// dataplane rules and programs them.
+package policies
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-container-networking/iptables"
+)
+
+// BaseChain is the well-known chain every per-policy chain is hooked into.
+const BaseChain = "AZURE-NPM"
+
+// ACLPolicy is a single iptables rule belonging to an NPMNetworkPolicy.
+type ACLPolicy struct {
+	Match  string
+	Target string
+}
+
+// NPMNetworkPolicy is the in-memory representation of a translated
+// Kubernetes NetworkPolicy, ready to be programmed into the dataplane.
+type NPMNetworkPolicy struct {
+	PolicyKey string
+	ACLs      []ACLPolicy
+}
+
+func (n *NPMNetworkPolicy) chainName() string {
+	return fmt.Sprintf("%s-%s", BaseChain, n.PolicyKey)
+}
+
+// policyState is the pending create or removal staged for one policy key
+// since the last successful ApplyPolicies. A nil netpol means the policy is
+// staged for removal.
+type policyState struct {
+	netpol *NPMNetworkPolicy
+}
+
+// PolicyManager stages NPMNetworkPolicy mutations in a dirty-set cache keyed
+// by policy name, and flushes them into iptables as a single
+// iptables-restore document per ApplyPolicies call rather than one
+// EnsureChain/EnsureRule per change. appliedChains tracks which policy
+// chains and base-chain jumps are already live from an earlier successful
+// ApplyPolicies, so re-adding a policy (e.g. NPM's periodic resync) stays
+// idempotent instead of re-issuing -N/-A against a chain iptables-restore
+// would reject as already existing.
+type PolicyManager struct {
+	iptablesClient  iptables.Interface
+	dirtyCache      map[string]*policyState
+	order           []string
+	lastGoodRestore string
+	appliedChains   map[string]bool
+}
+
+// NewPolicyManager creates a PolicyManager backed by iptablesClient. Passing
+// nil falls back to the real iptables binary, which production callers
+// should avoid in favor of an explicit iptables.NewClient(iptables.V4).
+func NewPolicyManager(iptablesClient iptables.Interface) *PolicyManager {
+	if iptablesClient == nil {
+		iptablesClient = iptables.NewClient(iptables.V4)
+	}
+
+	return &PolicyManager{iptablesClient: iptablesClient, dirtyCache: make(map[string]*policyState), appliedChains: make(map[string]bool)}
+}
+
+func (pMgr *PolicyManager) stage(policyKey string, state *policyState) {
+	if _, ok := pMgr.dirtyCache[policyKey]; !ok {
+		pMgr.order = append(pMgr.order, policyKey)
+	}
+
+	pMgr.dirtyCache[policyKey] = state
+}
+
+// AddPolicy stages netpol for programming on the next ApplyPolicies,
+// replacing whatever was staged for its policy key earlier in this batch.
+// endpointList is reserved for scoping the jump to specific pod endpoints
+// and is currently unused.
+func (pMgr *PolicyManager) AddPolicy(netpol *NPMNetworkPolicy, _ map[string]string) error {
+	pMgr.stage(netpol.PolicyKey, &policyState{netpol: netpol})
+	return nil
+}
+
+// RemovePolicy stages policyName's removal on the next ApplyPolicies,
+// discarding any add staged for it earlier in this batch. endpointList is
+// reserved for scoping the jump to specific pod endpoints and is currently
+// unused.
+func (pMgr *PolicyManager) RemovePolicy(policyName string, _ map[string]string) error {
+	pMgr.stage(policyName, &policyState{})
+	return nil
+}
+
+// UpdatePolicy re-stages netpol, replacing whatever was staged for its
+// policy key earlier in this batch. endpointList is reserved for scoping
+// the jump to specific pod endpoints and is currently unused.
+func (pMgr *PolicyManager) UpdatePolicy(netpol *NPMNetworkPolicy, endpointList map[string]string) error {
+	return pMgr.AddPolicy(netpol, endpointList)
+}
+
+// PendingRestore renders the iptables-restore document for every policy
+// staged since the last successful ApplyPolicies, in the order each policy
+// key was first touched in this batch so rule chains keep a stable,
+// predictable ordering.
+func (pMgr *PolicyManager) PendingRestore() string {
+	if len(pMgr.order) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s\n", iptables.Nat)
+
+	for _, policyKey := range pMgr.order {
+		state := pMgr.dirtyCache[policyKey]
+		chain := fmt.Sprintf("%s-%s", BaseChain, policyKey)
+
+		if state.netpol == nil {
+			fmt.Fprintf(&b, "-D %s  -j %s\n", BaseChain, chain)
+			fmt.Fprintf(&b, "-F %s\n", chain)
+			fmt.Fprintf(&b, "-X %s\n", chain)
+			continue
+		}
+
+		if pMgr.appliedChains[policyKey] {
+			// the chain and its base-chain jump are already live from an
+			// earlier successful apply; flush and reprogram its ACLs in
+			// place instead of re-issuing -N/-A against an existing chain.
+			fmt.Fprintf(&b, "-F %s\n", chain)
+		} else {
+			fmt.Fprintf(&b, "-N %s\n", chain)
+			fmt.Fprintf(&b, "-A %s  -j %s\n", BaseChain, chain)
+		}
+
+		for _, acl := range state.netpol.ACLs {
+			fmt.Fprintf(&b, "-A %s %s -j %s\n", chain, acl.Match, acl.Target)
+		}
+	}
+
+	fmt.Fprintln(&b, "COMMIT")
+
+	return b.String()
+}
+
+// ApplyPolicies flushes every policy staged since the last successful call
+// as one iptables-restore invocation and clears the dirty cache on
+// success. On failure the previous good document is replayed so the
+// dataplane is left exactly as it was before this batch.
+func (pMgr *PolicyManager) ApplyPolicies() error {
+	document := pMgr.PendingRestore()
+	if document == "" {
+		return nil
+	}
+
+	if err := pMgr.iptablesClient.Restore(document); err != nil {
+		if pMgr.lastGoodRestore != "" {
+			_ = pMgr.iptablesClient.Restore(pMgr.lastGoodRestore)
+		}
+
+		return fmt.Errorf("failed to apply policies: %w", err)
+	}
+
+	for _, policyKey := range pMgr.order {
+		if pMgr.dirtyCache[policyKey].netpol == nil {
+			delete(pMgr.appliedChains, policyKey)
+		} else {
+			pMgr.appliedChains[policyKey] = true
+		}
+	}
+
+	pMgr.lastGoodRestore = document
+	pMgr.dirtyCache = make(map[string]*policyState)
+	pMgr.order = nil
+
+	return nil
+}
@@ -1,23 +1,209 @@
 package policies
 
-import "testing"
+import (
+	"errors"
+	"testing"
 
-func TestAddPolicy(t *testing.T) {
-	pMgr := NewPolicyManager()
+	"github.com/Azure/azure-container-networking/iptables/mocks"
+	"github.com/golang/mock/gomock"
+)
 
-	netpol := NPMNetworkPolicy{}
+var errRestore = errors.New("iptables-restore: exit status 1")
 
-	err := pMgr.AddPolicy(&netpol, nil)
-	if err != nil {
-		t.Errorf("AddPolicy() returned error %s", err.Error())
+func TestAddPolicyStagesRestoreDocument(t *testing.T) {
+	pMgr := NewPolicyManager(nil)
+
+	netpol := NPMNetworkPolicy{
+		PolicyKey: "allow-frontend",
+		ACLs:      []ACLPolicy{{Match: "-s 10.0.0.0/24", Target: "ACCEPT"}},
+	}
+
+	if err := pMgr.AddPolicy(&netpol, nil); err != nil {
+		t.Fatalf("AddPolicy() returned error %s", err.Error())
+	}
+
+	want := "*nat\n" +
+		"-N AZURE-NPM-allow-frontend\n" +
+		"-A AZURE-NPM  -j AZURE-NPM-allow-frontend\n" +
+		"-A AZURE-NPM-allow-frontend -s 10.0.0.0/24 -j ACCEPT\n" +
+		"COMMIT\n"
+	if got := pMgr.PendingRestore(); got != want {
+		t.Errorf("PendingRestore() = %q, want %q", got, want)
 	}
 }
 
-func TestRemovePolicy(t *testing.T) {
-	pMgr := NewPolicyManager()
+func TestRemovePolicyStagesRestoreDocument(t *testing.T) {
+	pMgr := NewPolicyManager(nil)
 
-	err := pMgr.RemovePolicy("test", nil)
-	if err != nil {
-		t.Errorf("RemovePolicy() returned error %s", err.Error())
+	if err := pMgr.RemovePolicy("test", nil); err != nil {
+		t.Fatalf("RemovePolicy() returned error %s", err.Error())
+	}
+
+	want := "*nat\n" +
+		"-D AZURE-NPM  -j AZURE-NPM-test\n" +
+		"-F AZURE-NPM-test\n" +
+		"-X AZURE-NPM-test\n" +
+		"COMMIT\n"
+	if got := pMgr.PendingRestore(); got != want {
+		t.Errorf("PendingRestore() = %q, want %q", got, want)
+	}
+}
+
+func TestAddThenRemoveCollapsesWithinBatch(t *testing.T) {
+	pMgr := NewPolicyManager(nil)
+
+	netpol := NPMNetworkPolicy{
+		PolicyKey: "allow-frontend",
+		ACLs:      []ACLPolicy{{Match: "-s 10.0.0.0/24", Target: "ACCEPT"}},
+	}
+
+	if err := pMgr.AddPolicy(&netpol, nil); err != nil {
+		t.Fatalf("AddPolicy() returned error %s", err.Error())
+	}
+	if err := pMgr.RemovePolicy("allow-frontend", nil); err != nil {
+		t.Fatalf("RemovePolicy() returned error %s", err.Error())
+	}
+
+	// The add is superseded by the remove staged later in the same batch,
+	// so only the removal should survive - not both.
+	want := "*nat\n" +
+		"-D AZURE-NPM  -j AZURE-NPM-allow-frontend\n" +
+		"-F AZURE-NPM-allow-frontend\n" +
+		"-X AZURE-NPM-allow-frontend\n" +
+		"COMMIT\n"
+	if got := pMgr.PendingRestore(); got != want {
+		t.Errorf("PendingRestore() = %q, want %q", got, want)
+	}
+}
+
+func TestMultiplePoliciesPreserveStagingOrder(t *testing.T) {
+	pMgr := NewPolicyManager(nil)
+
+	second := NPMNetworkPolicy{PolicyKey: "second"}
+	first := NPMNetworkPolicy{PolicyKey: "first"}
+
+	if err := pMgr.AddPolicy(&second, nil); err != nil {
+		t.Fatalf("AddPolicy() returned error %s", err.Error())
+	}
+	if err := pMgr.AddPolicy(&first, nil); err != nil {
+		t.Fatalf("AddPolicy() returned error %s", err.Error())
+	}
+
+	want := "*nat\n" +
+		"-N AZURE-NPM-second\n" +
+		"-A AZURE-NPM  -j AZURE-NPM-second\n" +
+		"-N AZURE-NPM-first\n" +
+		"-A AZURE-NPM  -j AZURE-NPM-first\n" +
+		"COMMIT\n"
+	if got := pMgr.PendingRestore(); got != want {
+		t.Errorf("PendingRestore() = %q, want %q", got, want)
+	}
+}
+
+func TestApplyPoliciesCallsRestoreAndClearsCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	netpol := NPMNetworkPolicy{PolicyKey: "allow-frontend"}
+
+	iptablesClient := mocks.NewMockInterface(ctrl)
+	iptablesClient.EXPECT().Restore(gomock.Any()).Return(nil)
+
+	pMgr := NewPolicyManager(iptablesClient)
+	if err := pMgr.AddPolicy(&netpol, nil); err != nil {
+		t.Fatalf("AddPolicy() returned error %s", err.Error())
+	}
+
+	if err := pMgr.ApplyPolicies(); err != nil {
+		t.Fatalf("ApplyPolicies() returned error %s", err.Error())
+	}
+	if got := pMgr.PendingRestore(); got != "" {
+		t.Errorf("PendingRestore() after apply = %q, want empty", got)
+	}
+}
+
+func TestApplyPoliciesWithNoChangesIsNoop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// no EXPECT() calls: Restore must not be invoked for an empty batch.
+	iptablesClient := mocks.NewMockInterface(ctrl)
+
+	pMgr := NewPolicyManager(iptablesClient)
+
+	if err := pMgr.ApplyPolicies(); err != nil {
+		t.Fatalf("ApplyPolicies() returned error %s", err.Error())
+	}
+}
+
+func TestAddPolicyIsIdempotentAcrossApplies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	netpol := NPMNetworkPolicy{
+		PolicyKey: "allow-frontend",
+		ACLs:      []ACLPolicy{{Match: "-s 10.0.0.0/24", Target: "ACCEPT"}},
+	}
+
+	iptablesClient := mocks.NewMockInterface(ctrl)
+	iptablesClient.EXPECT().Restore(gomock.Any()).Return(nil).Times(2)
+
+	pMgr := NewPolicyManager(iptablesClient)
+
+	if err := pMgr.AddPolicy(&netpol, nil); err != nil {
+		t.Fatalf("AddPolicy() returned error %s", err.Error())
+	}
+	if err := pMgr.ApplyPolicies(); err != nil {
+		t.Fatalf("ApplyPolicies() returned error %s", err.Error())
+	}
+
+	// Re-adding the same policy after it was already applied (e.g. NPM's
+	// periodic resync) must not re-issue -N/-A against the chain
+	// iptables-restore already created - only flush and reprogram its ACLs.
+	if err := pMgr.AddPolicy(&netpol, nil); err != nil {
+		t.Fatalf("AddPolicy() returned error %s", err.Error())
+	}
+
+	want := "*nat\n" +
+		"-F AZURE-NPM-allow-frontend\n" +
+		"-A AZURE-NPM-allow-frontend -s 10.0.0.0/24 -j ACCEPT\n" +
+		"COMMIT\n"
+	if got := pMgr.PendingRestore(); got != want {
+		t.Errorf("PendingRestore() = %q, want %q", got, want)
+	}
+
+	if err := pMgr.ApplyPolicies(); err != nil {
+		t.Fatalf("ApplyPolicies() returned error %s", err.Error())
+	}
+}
+
+func TestApplyPoliciesRollsBackOnFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	good := NPMNetworkPolicy{PolicyKey: "already-applied"}
+	bad := NPMNetworkPolicy{PolicyKey: "breaks-restore"}
+
+	iptablesClient := mocks.NewMockInterface(ctrl)
+	gomock.InOrder(
+		iptablesClient.EXPECT().Restore(gomock.Any()).Return(nil),        // the good batch
+		iptablesClient.EXPECT().Restore(gomock.Any()).Return(errRestore), // the bad batch
+		iptablesClient.EXPECT().Restore(gomock.Any()).Return(nil),        // rollback to the good document
+	)
+
+	pMgr := NewPolicyManager(iptablesClient)
+
+	if err := pMgr.AddPolicy(&good, nil); err != nil {
+		t.Fatalf("AddPolicy() returned error %s", err.Error())
+	}
+	if err := pMgr.ApplyPolicies(); err != nil {
+		t.Fatalf("ApplyPolicies() returned error %s", err.Error())
+	}
+
+	if err := pMgr.AddPolicy(&bad, nil); err != nil {
+		t.Fatalf("AddPolicy() returned error %s", err.Error())
+	}
+	if err := pMgr.ApplyPolicies(); err == nil {
+		t.Error("ApplyPolicies() returned nil error, want non-nil")
 	}
 }
@@ -0,0 +1,52 @@
+// Package dataplane batches the ipset and iptables changes NPM computes from
+// Kubernetes NetworkPolicy/Pod events and programs them into the host
+// dataplane.
+package dataplane
+
+import (
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/ipsets"
+	"github.com/Azure/azure-container-networking/npm/pkg/dataplane/policies"
+)
+
+// UpdateNPMPod carries the subset of pod state NPM needs in order to keep
+// ipset membership (pod selectors, named ports, etc.) in sync.
+type UpdateNPMPod struct {
+	PodKey string
+	PodIP  string
+}
+
+// GenericDataplane is the interface NPM's controllers program against,
+// independent of the underlying OS dataplane (iptables+ipset on Linux, HNS
+// on Windows). Mutating calls do not take effect immediately: they stage
+// their intent in a dirty-set cache, and ApplyDataPlane flushes the
+// accumulated delta in one batch.
+type GenericDataplane interface {
+	InitializeDataPlane() error
+	ResetDataPlane() error
+
+	CreateIPSet(setMetadata *ipsets.IPSetMetadata)
+	DeleteIPSet(setMetadata *ipsets.IPSetMetadata)
+	AddToSet(setNames []*ipsets.IPSetMetadata, ip, podKey string) error
+	RemoveFromSet(setNames []*ipsets.IPSetMetadata, ip, podKey string) error
+	AddToList(listName *ipsets.IPSetMetadata, setNames []*ipsets.IPSetMetadata) error
+	RemoveFromList(listName *ipsets.IPSetMetadata, setNames []*ipsets.IPSetMetadata) error
+
+	AddPolicy(policy *policies.NPMNetworkPolicy) error
+	RemovePolicy(policyName string) error
+	UpdatePolicy(policy *policies.NPMNetworkPolicy) error
+
+	UpdatePod(pod *UpdateNPMPod) error
+
+	// ApplyDataPlane flushes every change staged since the last successful
+	// call as a single iptables-restore document plus one ipset restore
+	// stream. A failed restore rolls the dataplane back to the last
+	// successfully applied iptables document rather than leaving it
+	// partially programmed.
+	ApplyDataPlane() error
+
+	// PendingDataPlaneApply renders the iptables-restore and ipset restore
+	// documents that ApplyDataPlane would flush right now, without
+	// flushing them. It exists so tests can assert on the exact batch
+	// produced by a sequence of staged calls.
+	PendingDataPlaneApply() (iptablesRestore, ipsetRestore string)
+}